@@ -0,0 +1,124 @@
+//go:build integration
+// +build integration
+
+package s3sns
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMinIOListingAndPagination spins up a MinIO container, uploads a handful of objects, and
+// verifies S3Notify lists and paginates them correctly against a non-AWS S3-compatible backend,
+// exercising the Endpoint/S3ForcePathStyle/DisableSSL/Credentials overrides this package added.
+//
+// Requires Docker. Excluded from the default test run by the "integration" build tag:
+//
+//	go test -tags integration ./cmd/opstools/s3sns/...
+func TestMinIOListingAndPagination(t *testing.T) {
+	const (
+		accessKey  = "minioadmin"
+		secretKey  = "minioadmin"
+		port       = "19000"
+		bucket     = "s3sns-integration"
+		numObjects = pageSize + 1 // one more than a single ListObjectsV2 page holds
+	)
+
+	containerName := fmt.Sprintf("s3sns-minio-%d", time.Now().UnixNano())
+	run := exec.Command("docker", "run", "-d", "--rm",
+		"--name", containerName,
+		"-p", port+":9000",
+		"-e", "MINIO_ROOT_USER="+accessKey,
+		"-e", "MINIO_ROOT_PASSWORD="+secretKey,
+		"minio/minio", "server", "/data")
+	require.NoError(t, run.Run(), "failed to start MinIO container (is Docker running?)")
+	defer exec.Command("docker", "stop", containerName).Run()
+
+	endpoint := "http://localhost:" + port
+	waitForMinIO(t, endpoint)
+
+	sess := session.Must(session.NewSession())
+	creds := credentials.NewStaticCredentials(accessKey, secretKey, "")
+
+	s3Client := s3.New(sess.Copy(&aws.Config{
+		Region:           aws.String("us-east-1"),
+		Endpoint:         aws.String(endpoint),
+		S3ForcePathStyle: aws.Bool(true),
+		DisableSSL:       aws.Bool(true),
+		Credentials:      creds,
+	}))
+
+	_, err := s3Client.CreateBucket(&s3.CreateBucketInput{Bucket: aws.String(bucket)})
+	require.NoError(t, err)
+
+	for i := 0; i < numObjects; i++ {
+		key := fmt.Sprintf("prefix/obj-%04d.json", i)
+		_, err := s3Client.PutObject(&s3.PutObjectInput{
+			Bucket: aws.String(bucket),
+			Key:    aws.String(key),
+			Body:   bytes.NewReader([]byte(`{}`)),
+		})
+		require.NoError(t, err)
+	}
+
+	cfg := Config{
+		S3Path:           fmt.Sprintf("s3://%s/prefix/", bucket),
+		S3Region:         "us-east-1",
+		Endpoint:         endpoint,
+		S3ForcePathStyle: true,
+		DisableSSL:       true,
+		Credentials:      creds,
+		Concurrency:      1,
+	}
+
+	// Every Publish call carries at least one entry, so numObjects scripted successes always suffice.
+	notifier := &fakeNotifier{results: make([]fakeNotifierResult, numObjects)}
+	var stats Stats
+	require.NoError(t, S3Notify(sess, cfg, notifier, &stats))
+	require.EqualValues(t, numObjects, stats.NumFiles)
+}
+
+// waitForMinIO polls MinIO's health endpoint until it responds or the deadline passes.
+func waitForMinIO(t *testing.T, endpoint string) {
+	t.Helper()
+	deadline := time.Now().Add(30 * time.Second)
+	for time.Now().Before(deadline) {
+		resp, err := http.Get(endpoint + "/minio/health/live")
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				return
+			}
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+	t.Fatal("MinIO did not become healthy in time")
+}
@@ -0,0 +1,152 @@
+package main
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/panther-labs/panther/cmd/opstools/s3sns"
+	"github.com/panther-labs/panther/cmd/opstools/s3sns/metrics"
+)
+
+// dateFlagLayout is the format --from/--to/--since accept, e.g. "2024-01-31".
+const dateFlagLayout = "2006-01-02"
+
+func main() {
+	var (
+		destination = flag.String("destination", "", "notification sink URL: sns://<region>/<account>/<topic>, "+
+			"sqs://<region>/<account>/<queue>, eventbridge://<bus>, or kafka://<broker[,broker...]>/<topic>. "+
+			"Defaults to sns://<topic-region>/<account>/<topic> if --account/--topic/--topic-region are set instead.")
+		account     = flag.String("account", "", "AWS account id that owns the SNS topic (required unless --destination is set)")
+		s3Path      = flag.String("s3-path", "", "s3://bucket/prefix to list; may contain {yyyy}/{mm}/{dd} date tokens and shell-style brace expressions (required)")
+		s3Region    = flag.String("s3-region", "", "AWS region of the S3 bucket (defaults to the session region)")
+		topic       = flag.String("topic", "", "SNS topic name to publish to (required unless --destination is set)")
+		topicRegion = flag.String("topic-region", "", "AWS region of the SNS topic (defaults to the session region)")
+		concurrency = flag.Int("concurrency", 10, "number of concurrent publish workers")
+		limit       = flag.Uint64("limit", 0, "stop after this many files (0 means no limit)")
+
+		resumeFrom      = flag.String("resume-from", "", "path to a checkpoint file written by a previous, interrupted run; resumes listing from it instead of starting over")
+		checkpointTo    = flag.String("checkpoint-to", "", "path to write a checkpoint file to every --checkpoint-every pages, for a later --resume-from")
+		checkpointEvery = flag.Int("checkpoint-every", 0, "pages between checkpoint writes when --checkpoint-to is set (0 means the package default)")
+
+		from  = flag.String("from", "", "start of the date range, as "+dateFlagLayout+"; required if --s3-path has {yyyy}/{mm}/{dd} tokens")
+		to    = flag.String("to", "", "end of the date range (inclusive), as "+dateFlagLayout+"; required if --s3-path has {yyyy}/{mm}/{dd} tokens")
+		since = flag.String("since", "", "skip objects last modified before this date, as "+dateFlagLayout)
+
+		metricsAddr = flag.String("metrics-addr", "", "if set, serve Prometheus metrics on this address (e.g. :8080) at /metrics for the duration of the run")
+	)
+	flag.Parse()
+
+	if *s3Path == "" {
+		log.Fatal("--s3-path is required")
+	}
+	if *destination == "" && (*account == "" || *topic == "") {
+		log.Fatal("either --destination, or --account and --topic, are required")
+	}
+
+	fromTime := parseDateFlag("from", *from)
+	toTime := parseDateFlag("to", *to)
+	sinceTime := parseDateFlag("since", *since)
+
+	sess := session.Must(session.NewSession())
+	if *s3Region == "" {
+		*s3Region = *sess.Config.Region
+	}
+	if *topicRegion == "" {
+		*topicRegion = *sess.Config.Region
+	}
+
+	dest := *destination
+	if dest == "" {
+		dest = fmt.Sprintf("sns://%s/%s/%s", *topicRegion, *account, *topic)
+	}
+	notifier, err := s3sns.NewNotifier(sess, dest)
+	if err != nil {
+		log.Fatalf("bad --destination %q: %v", dest, err)
+	}
+
+	registry := prometheus.NewRegistry()
+	if *metricsAddr != "" {
+		go func() {
+			if err := metrics.Serve(*metricsAddr, registry); err != nil {
+				log.Printf("metrics server on %s stopped: %v", *metricsAddr, err)
+			}
+		}()
+	}
+
+	cfg := s3sns.Config{
+		S3Path:          *s3Path,
+		S3Region:        *s3Region,
+		Concurrency:     *concurrency,
+		Limit:           *limit,
+		CheckpointEvery: *checkpointEvery,
+		From:            fromTime,
+		To:              toTime,
+		Since:           sinceTime,
+		Metrics:         metrics.New(registry),
+	}
+
+	if *checkpointTo != "" {
+		f, createErr := os.Create(*checkpointTo)
+		if createErr != nil {
+			log.Fatalf("failed to create --checkpoint-to %q: %v", *checkpointTo, createErr)
+		}
+		defer f.Close()
+		cfg.CheckpointWriter = f
+	}
+
+	if *resumeFrom != "" {
+		f, openErr := os.Open(*resumeFrom)
+		if openErr != nil {
+			log.Fatalf("failed to open --resume-from %q: %v", *resumeFrom, openErr)
+		}
+		checkpoint, readErr := s3sns.ReadCheckpoint(f)
+		f.Close()
+		if readErr != nil {
+			log.Fatalf("failed to read checkpoint from %q: %v", *resumeFrom, readErr)
+		}
+		cfg.Resume = &checkpoint
+	}
+
+	var stats s3sns.Stats
+	if err := s3sns.S3Notify(sess, cfg, notifier, &stats); err != nil {
+		log.Fatalf("s3sns failed: %v", err)
+	}
+	log.Printf("done: %d files, %d bytes", stats.NumFiles, stats.NumBytes)
+}
+
+// parseDateFlag parses value (a "2006-01-02" date) for the named flag, or returns the zero time
+// if value is empty.
+func parseDateFlag(name, value string) time.Time {
+	if value == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(dateFlagLayout, value)
+	if err != nil {
+		log.Fatalf("bad --%s %q: %v", name, value, err)
+	}
+	return t
+}
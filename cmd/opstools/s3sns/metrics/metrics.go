@@ -0,0 +1,66 @@
+package metrics
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics holds the Prometheus collectors s3sns emits for a single lister/publisher run.
+// Construct one with New and share the same Registerer used elsewhere in the process (e.g. the
+// metrics_api Lambda's own router counters) so everything shows up on one /metrics scrape.
+type Metrics struct {
+	FilesListed    prometheus.Counter
+	BytesListed    prometheus.Counter
+	PublishErrors  *prometheus.CounterVec
+	PublishLatency prometheus.Histogram
+	QueueDepth     prometheus.Gauge
+}
+
+// New creates s3sns's Metrics and registers them on reg. reg may be nil, in which case the
+// collectors still record observations but are never exposed to a scraper.
+func New(reg prometheus.Registerer) *Metrics {
+	m := &Metrics{
+		FilesListed: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "panther_s3sns_files_listed_total",
+			Help: "Number of S3 objects listed by s3sns.",
+		}),
+		BytesListed: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "panther_s3sns_bytes_listed_total",
+			Help: "Total size in bytes of S3 objects listed by s3sns.",
+		}),
+		PublishErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "panther_s3sns_publish_errors_total",
+			Help: "Number of Notifier publish failures, by reason.",
+		}, []string{"reason"}),
+		PublishLatency: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "panther_s3sns_publish_latency_seconds",
+			Help:    "Latency of Notifier.Publish calls.",
+			Buckets: prometheus.DefBuckets,
+		}),
+		QueueDepth: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "panther_s3sns_queue_depth",
+			Help: "Number of S3 notifications buffered waiting to be published.",
+		}),
+	}
+	if reg != nil {
+		reg.MustRegister(m.FilesListed, m.BytesListed, m.PublishErrors, m.PublishLatency, m.QueueDepth)
+	}
+	return m
+}
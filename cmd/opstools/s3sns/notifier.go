@@ -0,0 +1,183 @@
+package s3sns
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/eventbridge"
+	"github.com/aws/aws-sdk-go/service/sns"
+	"github.com/aws/aws-sdk-go/service/sns/snsiface"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+)
+
+const (
+	// queueURLTemplate mirrors topicArnTemplate, but for the SQS queue URLs sqs:// destinations
+	// resolve to.
+	queueURLTemplate = "https://sqs.%s.amazonaws.com/%s/%s"
+)
+
+// Notifier delivers a batch of S3 notifications to a downstream sink: SNS, SQS, EventBridge,
+// Kafka, or anything else that implements it. Publish reports per-entry failures as a map keyed
+// by the entry's index in batch, so publishBatch can retry just those entries; a nil map with a
+// nil error means every entry in batch succeeded. A non-nil error return means the call failed
+// outright (e.g. a network error); publishBatch then fails every entry in batch without retrying
+// any of them. An entry that failed
+// to marshal should be reported as a marshalError (see newMarshalError) so publishBatch knows not
+// to waste retries on it.
+type Notifier interface {
+	Publish(ctx context.Context, batch []*events.S3Event) (failed map[int]error, err error)
+
+	// Close releases any resources the Notifier holds open (e.g. a Kafka writer's connections).
+	// Implementations backed by a stateless AWS API client (SNS, SQS, EventBridge) just return nil.
+	Close() error
+}
+
+// marshalError marks a Notifier.Publish failure that happened while encoding an entry, not while
+// talking to the destination. Re-sending the same entry would fail identically, so publishBatch
+// fails it immediately instead of burning retries and backoff on an error that can't change.
+type marshalError struct {
+	error
+}
+
+func newMarshalError(err error) marshalError {
+	return marshalError{err}
+}
+
+// NewNotifier builds a Notifier from a destination URL, so CLI/Lambda callers can select a sink
+// via config instead of wiring one up by hand. Supported schemes:
+//
+//	sns://<region>/<account>/<topic-name>
+//	sqs://<region>/<account>/<queue-name>
+//	eventbridge://<event-bus-name>
+//	kafka://<broker[,broker...]>/<topic>
+func NewNotifier(sess *session.Session, destination string) (Notifier, error) {
+	parsed, err := url.Parse(destination)
+	if err != nil {
+		return nil, errors.Wrapf(err, "bad notifier destination: %s", destination)
+	}
+
+	switch parsed.Scheme {
+	case "sns":
+		region, account, topic, err := splitRegionAccountName(parsed)
+		if err != nil {
+			return nil, errors.Wrap(err, "bad sns:// destination")
+		}
+		topicARN := fmt.Sprintf(topicArnTemplate, region, account, topic)
+		return newSNSNotifier(sns.New(sess, &aws.Config{Region: &region}), topicARN), nil
+
+	case "sqs":
+		region, account, queue, err := splitRegionAccountName(parsed)
+		if err != nil {
+			return nil, errors.Wrap(err, "bad sqs:// destination")
+		}
+		queueURL := fmt.Sprintf(queueURLTemplate, region, account, queue)
+		return newSQSNotifier(sqs.New(sess, &aws.Config{Region: &region}), queueURL), nil
+
+	case "eventbridge":
+		busName := parsed.Host
+		if busName == "" {
+			return nil, errors.Errorf("bad eventbridge:// destination %q: missing event bus name", destination)
+		}
+		return newEventBridgeNotifier(eventbridge.New(sess), busName), nil
+
+	case "kafka":
+		if parsed.Host == "" {
+			return nil, errors.Errorf("bad kafka:// destination %q: missing broker(s)", destination)
+		}
+		topic := strings.TrimPrefix(parsed.Path, "/")
+		if topic == "" {
+			return nil, errors.Errorf("bad kafka:// destination %q: missing topic", destination)
+		}
+		return newKafkaNotifier(strings.Split(parsed.Host, ","), topic), nil
+
+	default:
+		return nil, errors.Errorf("unsupported notifier destination scheme %q (want sns, sqs, eventbridge or kafka)", parsed.Scheme)
+	}
+}
+
+// splitRegionAccountName parses the <region>/<account>/<name> path shared by sns:// and sqs://
+// destinations, e.g. "sns://us-east-1/123456789012/my-topic".
+func splitRegionAccountName(parsed *url.URL) (region, account, name string, err error) {
+	region = parsed.Host
+	parts := strings.Split(strings.TrimPrefix(parsed.Path, "/"), "/")
+	if region == "" || len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", "", errors.Errorf("expected <scheme>://<region>/<account>/<name>, got %q", parsed.String())
+	}
+	return region, parts[0], parts[1], nil
+}
+
+// snsNotifier is the original SNS-backed Notifier: one SNS PublishBatch call per batch.
+type snsNotifier struct {
+	client   snsiface.SNSAPI
+	topicARN string
+}
+
+func newSNSNotifier(client snsiface.SNSAPI, topicARN string) *snsNotifier {
+	return &snsNotifier{client: client, topicARN: topicARN}
+}
+
+func (n *snsNotifier) Publish(ctx context.Context, batch []*events.S3Event) (map[int]error, error) {
+	entries := make([]*sns.PublishBatchRequestEntry, 0, len(batch))
+	idToIdx := make(map[string]int, len(batch))
+	failed := make(map[int]error)
+
+	for i, s3Notification := range batch {
+		notifyJSON, err := jsoniter.MarshalToString(s3Notification)
+		if err != nil {
+			failed[i] = newMarshalError(err)
+			continue
+		}
+		id := strconv.Itoa(i)
+		idToIdx[id] = i
+		entries = append(entries, &sns.PublishBatchRequestEntry{
+			Id:      aws.String(id),
+			Message: &notifyJSON,
+		})
+	}
+	if len(entries) == 0 {
+		return failed, nil
+	}
+
+	output, err := n.client.PublishBatchWithContext(ctx, &sns.PublishBatchInput{
+		TopicArn:                   &n.topicARN,
+		PublishBatchRequestEntries: entries,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, failure := range output.Failed {
+		failed[idToIdx[aws.StringValue(failure.Id)]] = errors.Errorf("%s: %s", aws.StringValue(failure.Code), aws.StringValue(failure.Message))
+	}
+	return failed, nil
+}
+
+// Close is a no-op; see Notifier.Close.
+func (n *snsNotifier) Close() error {
+	return nil
+}
@@ -0,0 +1,89 @@
+package s3sns
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/eventbridge"
+	"github.com/aws/aws-sdk-go/service/eventbridge/eventbridgeiface"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+)
+
+const (
+	// eventBridgeSource and eventBridgeDetailType identify s3sns's own events on the bus,
+	// distinct from the native S3->EventBridge notifications AWS can also be configured to send.
+	eventBridgeSource     = "panther.s3sns"
+	eventBridgeDetailType = "S3ObjectListed"
+)
+
+// eventBridgeNotifier delivers S3 notifications to an EventBridge event bus via PutEvents. Unlike
+// SNS/SQS, PutEvents entries and results have no id: success/failure is reported positionally,
+// in the same order the entries were submitted.
+type eventBridgeNotifier struct {
+	client  eventbridgeiface.EventBridgeAPI
+	busName string
+}
+
+func newEventBridgeNotifier(client eventbridgeiface.EventBridgeAPI, busName string) *eventBridgeNotifier {
+	return &eventBridgeNotifier{client: client, busName: busName}
+}
+
+func (n *eventBridgeNotifier) Publish(ctx context.Context, batch []*events.S3Event) (map[int]error, error) {
+	entries := make([]*eventbridge.PutEventsRequestEntry, 0, len(batch))
+	sentIdx := make([]int, 0, len(batch))
+	failed := make(map[int]error)
+
+	for i, s3Notification := range batch {
+		detail, err := jsoniter.MarshalToString(s3Notification)
+		if err != nil {
+			failed[i] = newMarshalError(err)
+			continue
+		}
+		entries = append(entries, &eventbridge.PutEventsRequestEntry{
+			EventBusName: aws.String(n.busName),
+			Source:       aws.String(eventBridgeSource),
+			DetailType:   aws.String(eventBridgeDetailType),
+			Detail:       aws.String(detail),
+		})
+		sentIdx = append(sentIdx, i)
+	}
+	if len(entries) == 0 {
+		return failed, nil
+	}
+
+	output, err := n.client.PutEventsWithContext(ctx, &eventbridge.PutEventsInput{Entries: entries})
+	if err != nil {
+		return nil, err
+	}
+	for i, result := range output.Entries {
+		if result.ErrorCode != nil {
+			failed[sentIdx[i]] = errors.Errorf("%s: %s", aws.StringValue(result.ErrorCode), aws.StringValue(result.ErrorMessage))
+		}
+	}
+	return failed, nil
+}
+
+// Close is a no-op; see Notifier.Close.
+func (n *eventBridgeNotifier) Close() error {
+	return nil
+}
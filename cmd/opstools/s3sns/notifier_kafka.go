@@ -0,0 +1,84 @@
+package s3sns
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+	"errors"
+
+	"github.com/aws/aws-lambda-go/events"
+	jsoniter "github.com/json-iterator/go"
+	kafka "github.com/segmentio/kafka-go"
+)
+
+// kafkaNotifier delivers S3 notifications to a Kafka topic via kafka-go's batching Writer.
+type kafkaNotifier struct {
+	writer *kafka.Writer
+}
+
+func newKafkaNotifier(brokers []string, topic string) *kafkaNotifier {
+	return &kafkaNotifier{
+		writer: &kafka.Writer{
+			Addr:     kafka.TCP(brokers...),
+			Topic:    topic,
+			Balancer: &kafka.LeastBytes{},
+		},
+	}
+}
+
+func (n *kafkaNotifier) Publish(ctx context.Context, batch []*events.S3Event) (map[int]error, error) {
+	msgs := make([]kafka.Message, 0, len(batch))
+	sentIdx := make([]int, 0, len(batch))
+	failed := make(map[int]error)
+
+	for i, s3Notification := range batch {
+		value, err := jsoniter.Marshal(s3Notification)
+		if err != nil {
+			failed[i] = newMarshalError(err)
+			continue
+		}
+		msgs = append(msgs, kafka.Message{Value: value})
+		sentIdx = append(sentIdx, i)
+	}
+	if len(msgs) == 0 {
+		return failed, nil
+	}
+
+	// WriteMessages reports per-message failures as a WriteErrors slice positionally aligned
+	// with msgs; any other error means the whole call failed outright.
+	var writeErrs kafka.WriteErrors
+	err := n.writer.WriteMessages(ctx, msgs...)
+	if errors.As(err, &writeErrs) {
+		for i, writeErr := range writeErrs {
+			if writeErr != nil {
+				failed[sentIdx[i]] = writeErr
+			}
+		}
+		return failed, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return failed, nil
+}
+
+// Close flushes and closes the underlying kafka.Writer's connections.
+func (n *kafkaNotifier) Close() error {
+	return n.writer.Close()
+}
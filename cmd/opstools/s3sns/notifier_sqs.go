@@ -0,0 +1,81 @@
+package s3sns
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+)
+
+// sqsNotifier delivers S3 notifications to an SQS queue via SendMessageBatch.
+type sqsNotifier struct {
+	client   sqsiface.SQSAPI
+	queueURL string
+}
+
+func newSQSNotifier(client sqsiface.SQSAPI, queueURL string) *sqsNotifier {
+	return &sqsNotifier{client: client, queueURL: queueURL}
+}
+
+func (n *sqsNotifier) Publish(ctx context.Context, batch []*events.S3Event) (map[int]error, error) {
+	entries := make([]*sqs.SendMessageBatchRequestEntry, 0, len(batch))
+	idToIdx := make(map[string]int, len(batch))
+	failed := make(map[int]error)
+
+	for i, s3Notification := range batch {
+		body, err := jsoniter.MarshalToString(s3Notification)
+		if err != nil {
+			failed[i] = newMarshalError(err)
+			continue
+		}
+		id := strconv.Itoa(i)
+		idToIdx[id] = i
+		entries = append(entries, &sqs.SendMessageBatchRequestEntry{
+			Id:          aws.String(id),
+			MessageBody: aws.String(body),
+		})
+	}
+	if len(entries) == 0 {
+		return failed, nil
+	}
+
+	output, err := n.client.SendMessageBatchWithContext(ctx, &sqs.SendMessageBatchInput{
+		QueueUrl: aws.String(n.queueURL),
+		Entries:  entries,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, failure := range output.Failed {
+		failed[idToIdx[aws.StringValue(failure.Id)]] = errors.Errorf("%s: %s", aws.StringValue(failure.Code), aws.StringValue(failure.Message))
+	}
+	return failed, nil
+}
+
+// Close is a no-op; see Notifier.Close.
+func (n *sqsNotifier) Close() error {
+	return nil
+}
@@ -0,0 +1,113 @@
+package s3sns
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewNotifierSchemes(t *testing.T) {
+	sess := session.Must(session.NewSession())
+
+	t.Run("sns", func(t *testing.T) {
+		notifier, err := NewNotifier(sess, "sns://us-east-1/123456789012/my-topic")
+		require.NoError(t, err)
+		require.IsType(t, &snsNotifier{}, notifier)
+	})
+
+	t.Run("sqs", func(t *testing.T) {
+		notifier, err := NewNotifier(sess, "sqs://us-east-1/123456789012/my-queue")
+		require.NoError(t, err)
+		require.IsType(t, &sqsNotifier{}, notifier)
+	})
+
+	t.Run("eventbridge", func(t *testing.T) {
+		notifier, err := NewNotifier(sess, "eventbridge://my-bus")
+		require.NoError(t, err)
+		require.IsType(t, &eventBridgeNotifier{}, notifier)
+	})
+
+	t.Run("eventbridge missing bus name", func(t *testing.T) {
+		_, err := NewNotifier(sess, "eventbridge://")
+		require.Error(t, err)
+	})
+
+	t.Run("kafka", func(t *testing.T) {
+		notifier, err := NewNotifier(sess, "kafka://broker-1:9092,broker-2:9092/my-topic")
+		require.NoError(t, err)
+		require.IsType(t, &kafkaNotifier{}, notifier)
+	})
+
+	t.Run("kafka missing brokers", func(t *testing.T) {
+		_, err := NewNotifier(sess, "kafka:///my-topic")
+		require.Error(t, err)
+	})
+
+	t.Run("kafka missing topic", func(t *testing.T) {
+		_, err := NewNotifier(sess, "kafka://broker-1:9092")
+		require.Error(t, err)
+	})
+
+	t.Run("unsupported scheme", func(t *testing.T) {
+		_, err := NewNotifier(sess, "http://example.com")
+		require.Error(t, err)
+	})
+
+	t.Run("unparseable destination", func(t *testing.T) {
+		_, err := NewNotifier(sess, "://not a url")
+		require.Error(t, err)
+	})
+}
+
+func TestSplitRegionAccountName(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		parsed, err := url.Parse("sns://us-east-1/123456789012/my-topic")
+		require.NoError(t, err)
+		region, account, name, err := splitRegionAccountName(parsed)
+		require.NoError(t, err)
+		require.Equal(t, "us-east-1", region)
+		require.Equal(t, "123456789012", account)
+		require.Equal(t, "my-topic", name)
+	})
+
+	t.Run("missing region", func(t *testing.T) {
+		parsed, err := url.Parse("sns:///123456789012/my-topic")
+		require.NoError(t, err)
+		_, _, _, err = splitRegionAccountName(parsed)
+		require.Error(t, err)
+	})
+
+	t.Run("missing account or name", func(t *testing.T) {
+		parsed, err := url.Parse("sns://us-east-1/123456789012")
+		require.NoError(t, err)
+		_, _, _, err = splitRegionAccountName(parsed)
+		require.Error(t, err)
+	})
+
+	t.Run("extra path segment", func(t *testing.T) {
+		parsed, err := url.Parse("sns://us-east-1/123456789012/my-topic/extra")
+		require.NoError(t, err)
+		_, _, _, err = splitRegionAccountName(parsed)
+		require.Error(t, err)
+	})
+}
@@ -19,62 +19,289 @@ package s3sns
  */
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"log"
 	"math"
 	"net/url"
 	"sync"
+	"time"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/s3/s3iface"
 	"github.com/aws/aws-sdk-go/service/sns"
-	"github.com/aws/aws-sdk-go/service/sns/snsiface"
 	jsoniter "github.com/json-iterator/go"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/cmd/opstools/s3sns/metrics"
 )
 
 const (
 	pageSize         = 1000
 	topicArnTemplate = "arn:aws:sns:%s:%s:%s"
 	progressNotify   = 5000 // log a line every this many to show progress
+
+	// defaultBatchSize is the largest batch SNS PublishBatch will accept in one call.
+	defaultBatchSize = 10
+	// defaultFlushInterval bounds how long a partial batch waits for more messages before being sent.
+	defaultFlushInterval = 200 * time.Millisecond
+	// maxPublishRetries is how many times a failed batch entry is resent before we give up on it.
+	maxPublishRetries   = 3
+	initialRetryBackoff = 250 * time.Millisecond
+
+	// defaultCheckpointEvery is how many ListObjectsV2 pages elapse between checkpoint writes
+	// when a CheckpointWriter is configured but no explicit CheckpointEvery is given.
+	defaultCheckpointEvery = 10
 )
 
 type Stats struct {
 	NumFiles uint64
 	NumBytes uint64
+
+	// ContinuationToken and LastKey track listing progress so a crashed or timed-out run can
+	// be resumed with S3TopicResume instead of starting over. They are updated as pages arrive
+	// and are only meaningful while (or after) a listing with a CheckpointWriter is in flight.
+	// When S3Path expands to more than one prefix (see ExpandS3PathTemplate), they reflect
+	// whichever prefix's page was processed most recently, not any one prefix in particular.
+	ContinuationToken string
+	LastKey           string
+
+	// mu guards every field above, since an expanded S3Path template is listed with one goroutine
+	// per concrete prefix, up to Config.Concurrency at a time.
+	mu sync.Mutex
+}
+
+// recordFile updates Stats for one listed object and returns the new NumFiles total, so callers
+// can check it against a shared limit even while multiple prefixes are listed concurrently.
+func (s *Stats) recordFile(size int64, key, continuationToken string) uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.NumFiles++
+	s.NumBytes += uint64(size)
+	s.LastKey = key
+	s.ContinuationToken = continuationToken
+	return s.NumFiles
+}
+
+// lastKey returns the most recently recorded LastKey under mu, since listPath reads it from a
+// goroutine that may run concurrently with other prefixes' recordFile calls.
+func (s *Stats) lastKey() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.LastKey
+}
+
+// Checkpoint is the resumable position of a listing: either a ContinuationToken from a prior
+// ListObjectsV2Output, or the last key seen (StartAfter), whichever the caller persisted.
+type Checkpoint struct {
+	ContinuationToken string `json:"continuationToken,omitempty"`
+	StartAfter        string `json:"startAfter,omitempty"`
+}
+
+// ReadCheckpoint decodes the small on-disk JSON format written by WriteCheckpoint, e.g. for a
+// CLI's --resume-from flag. A CheckpointWriter receives one checkpoint per line over a run, so
+// when r holds several, the last (most recent) one is returned.
+func ReadCheckpoint(r io.Reader) (checkpoint Checkpoint, err error) {
+	decoder := jsoniter.NewDecoder(r)
+	if err := decoder.Decode(&checkpoint); err != nil {
+		return Checkpoint{}, errors.Wrap(err, "failed to read checkpoint")
+	}
+	for decoder.More() {
+		var next Checkpoint
+		if err := decoder.Decode(&next); err != nil {
+			return Checkpoint{}, errors.Wrap(err, "failed to read checkpoint")
+		}
+		checkpoint = next
+	}
+	return checkpoint, nil
+}
+
+// WriteCheckpoint writes the small on-disk JSON format read by ReadCheckpoint.
+func WriteCheckpoint(w io.Writer, checkpoint Checkpoint) error {
+	data, err := jsoniter.Marshal(checkpoint)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal checkpoint")
+	}
+	data = append(data, '\n')
+	_, err = w.Write(data)
+	return errors.Wrap(err, "failed to write checkpoint")
+}
+
+// Config collects the parameters needed to list an S3 path and publish a notification per object.
+//
+// The zero value talks to AWS S3 and SNS directly, matching the original behavior of S3Topic.
+// Endpoint, S3ForcePathStyle, DisableSSL and Credentials are there for operators who want to
+// point the lister at an S3-compatible backend (MinIO, Ceph RGW, LocalStack) instead of AWS.
+type Config struct {
+	Account     string
+	S3Path      string
+	S3Region    string
+	Topic       string
+	TopicRegion string
+	Concurrency int
+	Limit       uint64
+
+	// BatchSize is how many notifications are sent per SNS PublishBatch call, up to the SNS
+	// limit of 10. Zero means defaultBatchSize.
+	BatchSize int
+	// FlushInterval bounds how long a partial batch is held open waiting for more messages
+	// before being sent anyway. Zero means defaultFlushInterval.
+	FlushInterval time.Duration
+
+	// Endpoint overrides the S3 endpoint, e.g. "http://localhost:9000" for a local MinIO.
+	Endpoint string
+	// S3ForcePathStyle is required by most non-AWS S3-compatible backends (bucket.region.amazonaws.com
+	// style virtual-hosted addressing does not resolve for them).
+	S3ForcePathStyle bool
+	// DisableSSL lets tests/LocalStack talk plain HTTP to the endpoint above.
+	DisableSSL bool
+	// Credentials overrides the session's credential chain, e.g. for a MinIO access/secret key pair.
+	Credentials *credentials.Credentials
+
+	// SNSEndpoint overrides the SNS endpoint, e.g. for a LocalStack SNS in tests.
+	SNSEndpoint string
+
+	// Resume seeds the listing's ListObjectsV2Input from a checkpoint persisted by a previous,
+	// interrupted run (see ReadCheckpoint). Nil starts from the beginning of S3Path.
+	Resume *Checkpoint
+	// CheckpointWriter, if set, receives a Checkpoint (see WriteCheckpoint) every CheckpointEvery
+	// pages so a crashed or timed-out run can be continued later via Resume.
+	CheckpointWriter io.Writer
+	// CheckpointEvery is how many ListObjectsV2 pages elapse between checkpoint writes. Zero
+	// means defaultCheckpointEvery.
+	CheckpointEvery int
+
+	// Metrics, if set, receives Prometheus observations for this run (files/bytes listed,
+	// publish errors/latency, queue depth). Construct one with metrics.New and share it with the
+	// Registerer used elsewhere in the process so everything shows up on one /metrics scrape.
+	// Nil creates an unregistered Metrics, so observations are still recorded but not exposed.
+	Metrics *metrics.Metrics
+
+	// From and To bound {yyyy}/{mm}/{dd} date-token expansion in S3Path (see
+	// ExpandS3PathTemplate). Ignored when S3Path has no date tokens. Prefixes are also expanded
+	// for any brace expressions S3Path contains ("{01..12}", "{a,b,c}"), independent of From/To.
+	From time.Time
+	To   time.Time
+
+	// Since, if non-zero, skips objects whose LastModified predates it and short-circuits a
+	// prefix's listing once a whole page predates it, on the assumption that a date-partitioned
+	// prefix's keys come back in roughly chronological order.
+	Since time.Time
 }
 
 func S3Topic(sess *session.Session, account, s3path, s3region, topic string,
 	concurrency int, limit uint64, stats *Stats) (err error) {
 
-	return s3sns(s3.New(sess.Copy(&aws.Config{Region: &s3region})), sns.New(sess),
-		account, s3path, topic, *sess.Config.Region, concurrency, limit, stats)
+	return S3TopicWithConfig(sess, Config{
+		Account:     account,
+		S3Path:      s3path,
+		S3Region:    s3region,
+		Topic:       topic,
+		TopicRegion: *sess.Config.Region,
+		Concurrency: concurrency,
+		Limit:       limit,
+	}, stats)
+}
+
+// S3TopicResume is the same as S3TopicWithConfig but continues a previously interrupted listing
+// from checkpoint instead of starting over from the beginning of cfg.S3Path.
+func S3TopicResume(sess *session.Session, cfg Config, checkpoint Checkpoint, stats *Stats) (err error) {
+	cfg.Resume = &checkpoint
+	return S3TopicWithConfig(sess, cfg, stats)
+}
+
+// S3TopicWithConfig is the same as S3Topic but allows overriding the S3/SNS endpoints, useful
+// for listing against MinIO, Ceph RGW or LocalStack instead of AWS. It's a thin wrapper around
+// S3Notify for backward compatibility; new callers that want a sink other than SNS should build
+// a Notifier (see NewNotifier) and call S3Notify directly.
+func S3TopicWithConfig(sess *session.Session, cfg Config, stats *Stats) (err error) {
+	snsConfig := aws.Config{}
+	if cfg.SNSEndpoint != "" {
+		snsConfig.Endpoint = &cfg.SNSEndpoint
+	}
+	topicARN := fmt.Sprintf(topicArnTemplate, cfg.TopicRegion, cfg.Account, cfg.Topic)
+
+	return S3Notify(sess, cfg, newSNSNotifier(sns.New(sess, &snsConfig), topicARN), stats)
 }
 
-func s3sns(s3Client s3iface.S3API, snsClient snsiface.SNSAPI, account, s3path, topic, topicRegion string,
-	concurrency int, limit uint64, stats *Stats) (failed error) {
+// S3Notify is the primary entry point: it lists cfg.S3Path (expanding any template, see
+// ExpandS3PathTemplate) and hands every object found to notifier, instead of hard-coding SNS, so
+// the same lister can feed SQS, EventBridge, Kafka or anything else a Notifier wraps. notifier is
+// closed once this run finishes; callers that want to reuse one across multiple S3Notify calls
+// (e.g. a long-lived kafkaNotifier) should build a fresh one per call with NewNotifier instead.
+func S3Notify(sess *session.Session, cfg Config, notifier Notifier, stats *Stats) (err error) {
+	defer func() {
+		if closeErr := notifier.Close(); closeErr != nil && err == nil {
+			err = errors.Wrap(closeErr, "failed to close notifier")
+		}
+	}()
+
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defaultBatchSize
+	}
+	if cfg.BatchSize > defaultBatchSize {
+		cfg.BatchSize = defaultBatchSize // SNS/SQS/EventBridge batch APIs all cap at 10 entries
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = defaultFlushInterval
+	}
+	if cfg.CheckpointEvery <= 0 {
+		cfg.CheckpointEvery = defaultCheckpointEvery
+	}
+	if cfg.Metrics == nil {
+		cfg.Metrics = metrics.New(nil)
+	}
+
+	s3Config := aws.Config{Region: &cfg.S3Region}
+	if cfg.Endpoint != "" {
+		s3Config.Endpoint = &cfg.Endpoint
+	}
+	if cfg.S3ForcePathStyle {
+		s3Config.S3ForcePathStyle = aws.Bool(true)
+	}
+	if cfg.DisableSSL {
+		s3Config.DisableSSL = aws.Bool(true)
+	}
+	if cfg.Credentials != nil {
+		s3Config.Credentials = cfg.Credentials
+	}
+
+	s3paths, err := ExpandS3PathTemplate(cfg.S3Path, cfg.From, cfg.To)
+	if err != nil {
+		return errors.Wrapf(err, "bad S3Path template %q", cfg.S3Path)
+	}
 
-	topicARN := fmt.Sprintf(topicArnTemplate, topicRegion, account, topic)
+	return s3sns(s3.New(sess.Copy(&s3Config)), notifier, s3paths,
+		cfg.Concurrency, cfg.BatchSize, cfg.FlushInterval, cfg.Limit, cfg.Since,
+		cfg.Resume, cfg.CheckpointWriter, cfg.CheckpointEvery, cfg.Metrics, stats)
+}
+
+func s3sns(s3Client s3iface.S3API, notifier Notifier, s3paths []string,
+	concurrency, batchSize int, flushInterval time.Duration, limit uint64, since time.Time,
+	resume *Checkpoint, checkpointWriter io.Writer, checkpointEvery int, m *metrics.Metrics, stats *Stats) (failed error) {
 
 	errChan := make(chan error)
 	notifyChan := make(chan *events.S3Event, 1000)
 
+	ctx := context.Background()
 	var queueWg sync.WaitGroup
 	for i := 0; i < concurrency; i++ {
 		queueWg.Add(1)
 		go func() {
-			publishNotifications(snsClient, topicARN, notifyChan, errChan)
+			publishNotifications(ctx, notifier, batchSize, flushInterval, notifyChan, errChan, m)
 			queueWg.Done()
 		}()
 	}
 
 	queueWg.Add(1)
 	go func() {
-		listPath(s3Client, s3path, limit, notifyChan, errChan, stats)
+		listPaths(s3Client, s3paths, limit, since, resume, checkpointWriter, checkpointEvery, concurrency, notifyChan, errChan, stats, m)
 		queueWg.Done()
 	}()
 
@@ -94,18 +321,59 @@ func s3sns(s3Client s3iface.S3API, snsClient snsiface.SNSAPI, account, s3path, t
 	return failed
 }
 
-// Given an s3path (e.g., s3://mybucket/myprefix) list files and send to notifyChan
-func listPath(s3Client s3iface.S3API, s3path string, limit uint64,
-	notifyChan chan *events.S3Event, errChan chan error, stats *Stats) {
+// listPaths lists s3paths (the output of ExpandS3PathTemplate) concurrently, up to concurrency
+// prefixes at a time, merging every prefix's objects into the same notifyChan/errChan/stats.
+// resume/checkpointWriter only apply when there's exactly one path: resuming a fan-out across
+// many expanded prefixes isn't supported, since there's no single continuation point for the set.
+func listPaths(s3Client s3iface.S3API, s3paths []string, limit uint64, since time.Time,
+	resume *Checkpoint, checkpointWriter io.Writer, checkpointEvery, concurrency int,
+	notifyChan chan *events.S3Event, errChan chan error, stats *Stats, m *metrics.Metrics) {
+
+	defer close(notifyChan) // signal to reader that we are done
+
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	sem := make(chan struct{}, concurrency)
+	var pathsWg sync.WaitGroup
+	for _, s3path := range s3paths {
+		s3path := s3path
+
+		var pathResume *Checkpoint
+		pathCheckpointWriter := checkpointWriter
+		if len(s3paths) > 1 {
+			pathCheckpointWriter = nil // no single continuation point for a multi-prefix fan-out
+		} else {
+			pathResume = resume
+		}
+
+		sem <- struct{}{}
+		pathsWg.Add(1)
+		go func() {
+			defer pathsWg.Done()
+			defer func() { <-sem }()
+			listPath(s3Client, s3path, limit, since, pathResume, pathCheckpointWriter, checkpointEvery, notifyChan, errChan, stats, m)
+		}()
+	}
+	pathsWg.Wait()
+}
+
+// Given an s3path (e.g., s3://mybucket/myprefix) list files and send to notifyChan. If since is
+// non-zero, objects with an older LastModified are skipped and a page that is entirely older
+// than since short-circuits the rest of the listing (date-partitioned prefixes return keys in
+// roughly chronological order). If resume is non-nil, listing starts from its
+// ContinuationToken/StartAfter instead of the beginning of s3path. If checkpointWriter is
+// non-nil, a Checkpoint is written to it every checkpointEvery pages so the listing can be
+// continued later via S3TopicResume.
+func listPath(s3Client s3iface.S3API, s3path string, limit uint64, since time.Time, resume *Checkpoint,
+	checkpointWriter io.Writer, checkpointEvery int,
+	notifyChan chan *events.S3Event, errChan chan error, stats *Stats, m *metrics.Metrics) {
 
 	if limit == 0 {
 		limit = math.MaxUint64
 	}
 
-	defer func() {
-		close(notifyChan) // signal to reader that we are done
-	}()
-
 	parsedPath, err := url.Parse(s3path)
 	if err != nil {
 		errChan <- errors.Errorf("bad s3 url: %s,", err)
@@ -133,14 +401,31 @@ func listPath(s3Client s3iface.S3API, s3path string, limit uint64,
 		Prefix:  aws.String(prefix),
 		MaxKeys: aws.Int64(pageSize),
 	}
+	if resume != nil {
+		if resume.ContinuationToken != "" {
+			inputParams.ContinuationToken = aws.String(resume.ContinuationToken)
+		} else if resume.StartAfter != "" {
+			inputParams.StartAfter = aws.String(resume.StartAfter)
+		}
+	}
+
+	var pageNum int
+	var numFiles uint64
 	err = s3Client.ListObjectsV2Pages(inputParams, func(page *s3.ListObjectsV2Output, morePages bool) bool {
+		pageHasRecent := since.IsZero()
 		for _, value := range page.Contents {
+			if !since.IsZero() && value.LastModified != nil && value.LastModified.Before(since) {
+				continue // older than --since, skip but keep paging in case later keys are newer
+			}
+			pageHasRecent = true
 			if *value.Size > 0 { // we only care about objects with size
-				stats.NumFiles++
-				if stats.NumFiles%progressNotify == 0 {
-					log.Printf("listed %d files ...", stats.NumFiles)
+				continuationToken := aws.StringValue(page.NextContinuationToken)
+				numFiles = stats.recordFile(*value.Size, *value.Key, continuationToken)
+				if numFiles%progressNotify == 0 {
+					log.Printf("listed %d files ...", numFiles)
 				}
-				stats.NumBytes += (uint64)(*value.Size)
+				m.FilesListed.Inc()
+				m.BytesListed.Add(float64(*value.Size))
 				notifyChan <- &events.S3Event{
 					Records: []events.S3EventRecord{
 						{
@@ -155,49 +440,167 @@ func listPath(s3Client s3iface.S3API, s3path string, limit uint64,
 						},
 					},
 				}
-				if stats.NumFiles >= limit {
+				if numFiles >= limit {
 					break
 				}
 			}
 		}
-		return stats.NumFiles < limit // "To stop iterating, return false from the fn function."
+
+		pageNum++
+		if checkpointWriter != nil && pageNum%checkpointEvery == 0 {
+			checkpoint := Checkpoint{ContinuationToken: aws.StringValue(page.NextContinuationToken), StartAfter: stats.lastKey()}
+			if err := WriteCheckpoint(checkpointWriter, checkpoint); err != nil {
+				errChan <- err
+			}
+		}
+
+		if !pageHasRecent {
+			return false // this page, and likely all following for a chronologically-ordered prefix, predates --since
+		}
+		return numFiles < limit // "To stop iterating, return false from the fn function."
 	})
 	if err != nil {
 		errChan <- err
 	}
 }
 
-// post message per file as-if it was an S3 notification
-func publishNotifications(snsClient snsiface.SNSAPI, topicARN string,
-	notifyChan chan *events.S3Event, errChan chan error) {
+// post messages for files as-if they were S3 notifications, batching up to batchSize per
+// notifier.Publish call and flushing early if flushInterval elapses with a partial batch pending.
+func publishNotifications(ctx context.Context, notifier Notifier, batchSize int,
+	flushInterval time.Duration, notifyChan chan *events.S3Event, errChan chan error, m *metrics.Metrics) {
 
 	var failed bool
-	for s3Notification := range notifyChan {
-		if failed { // drain channel
-			continue
+	batch := make([]*events.S3Event, 0, batchSize)
+
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	flush := func() {
+		if len(batch) == 0 {
+			return
 		}
+		if !failed {
+			if err := publishBatch(ctx, notifier, batch, errChan, m); err != nil {
+				failed = true
+			}
+		}
+		batch = batch[:0]
+	}
 
-		zap.L().Debug("sending file to SNS",
-			zap.String("bucket", s3Notification.Records[0].S3.Bucket.Name),
-			zap.String("key", s3Notification.Records[0].S3.Object.Key))
+	for {
+		select {
+		case s3Notification, ok := <-notifyChan:
+			m.QueueDepth.Set(float64(len(notifyChan)))
+			if !ok {
+				flush()
+				return
+			}
+			if failed { // drain channel
+				continue
+			}
 
-		notifyJSON, err := jsoniter.MarshalToString(s3Notification)
-		if err != nil {
-			errChan <- errors.Wrapf(err, "failed to marshal %#v", s3Notification)
-			failed = true
-			continue
+			zap.L().Debug("queueing file for notifier",
+				zap.String("bucket", s3Notification.Records[0].S3.Bucket.Name),
+				zap.String("key", s3Notification.Records[0].S3.Object.Key))
+
+			batch = append(batch, s3Notification)
+			if len(batch) >= batchSize {
+				flush()
+			}
+
+		case <-ticker.C:
+			flush()
 		}
+	}
+}
 
-		publishInput := &sns.PublishInput{
-			Message:  &notifyJSON,
-			TopicArn: &topicARN,
+// pendingEntry is one batch entry publishBatch is still trying to deliver, plus the most recent
+// error the notifier reported for it (nil until the first failed attempt).
+type pendingEntry struct {
+	event *events.S3Event
+	cause error
+}
+
+// publishBatch calls notifier.Publish, retrying only the entries it reports as failed (with
+// exponential backoff) before reporting whatever still didn't make it after maxPublishRetries. An
+// entry a notifier reports as a marshalError fails immediately instead: re-marshaling the same
+// object will never succeed, so retrying it would just burn the backoff for nothing.
+func publishBatch(ctx context.Context, notifier Notifier, batch []*events.S3Event, errChan chan error, m *metrics.Metrics) error {
+	pending := make([]pendingEntry, len(batch))
+	for i, s3Notification := range batch {
+		pending[i] = pendingEntry{event: s3Notification}
+	}
+
+	var failed error
+	giveUp := func(entry pendingEntry, cause error, reason string) {
+		failed = publishError(entry.event, cause)
+		errChan <- failed
+		m.PublishErrors.WithLabelValues(reason).Inc()
+	}
+
+	backoff := initialRetryBackoff
+	for attempt := 0; attempt <= maxPublishRetries && len(pending) > 0; attempt++ {
+		if attempt > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
 		}
 
-		_, err = snsClient.Publish(publishInput)
+		attemptBatch := make([]*events.S3Event, len(pending))
+		for i, entry := range pending {
+			attemptBatch[i] = entry.event
+		}
+
+		start := time.Now()
+		failedIdx, err := notifier.Publish(ctx, attemptBatch)
+		m.PublishLatency.Observe(time.Since(start).Seconds())
 		if err != nil {
-			errChan <- errors.Wrapf(err, "failed to publish %#v", *publishInput)
-			failed = true
-			continue
+			m.PublishErrors.WithLabelValues("api").Inc()
+			for _, entry := range pending {
+				errChan <- publishError(entry.event, err)
+			}
+			return err
+		}
+		if len(failedIdx) == 0 {
+			pending = nil
+			break
+		}
+
+		next := make([]pendingEntry, 0, len(failedIdx))
+		for i, entry := range pending {
+			cause, ok := failedIdx[i]
+			if !ok {
+				continue
+			}
+			if _, isMarshalErr := cause.(marshalError); isMarshalErr {
+				zap.L().Warn("notifier entry failed to marshal, giving up",
+					zap.String("bucket", entry.event.Records[0].S3.Bucket.Name),
+					zap.String("key", entry.event.Records[0].S3.Object.Key),
+					zap.Error(cause))
+				giveUp(entry, cause, "marshal")
+				continue
+			}
+			zap.L().Warn("notifier entry failed, will retry",
+				zap.String("bucket", entry.event.Records[0].S3.Bucket.Name),
+				zap.String("key", entry.event.Records[0].S3.Object.Key),
+				zap.Error(cause),
+				zap.Int("attempt", attempt))
+			m.PublishErrors.WithLabelValues("rejected").Inc()
+			next = append(next, pendingEntry{event: entry.event, cause: cause})
 		}
+		pending = next
 	}
+
+	for _, entry := range pending {
+		cause := entry.cause
+		if cause == nil {
+			cause = errors.New("giving up after retries")
+		}
+		giveUp(entry, cause, "exhausted")
+	}
+	return failed
+}
+
+func publishError(s3Notification *events.S3Event, cause error) error {
+	s3Entity := s3Notification.Records[0].S3
+	return errors.Wrapf(cause, "failed to publish s3://%s/%s", s3Entity.Bucket.Name, s3Entity.Object.Key)
 }
@@ -0,0 +1,227 @@
+package s3sns
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/cmd/opstools/s3sns/metrics"
+)
+
+func TestCheckpointRoundTrip(t *testing.T) {
+	checkpoint := Checkpoint{ContinuationToken: "token-1", StartAfter: "logs/2024/01/01/a.json"}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteCheckpoint(&buf, checkpoint))
+
+	read, err := ReadCheckpoint(&buf)
+	require.NoError(t, err)
+	require.Equal(t, checkpoint, read)
+}
+
+func TestReadCheckpointBadJSON(t *testing.T) {
+	_, err := ReadCheckpoint(bytes.NewBufferString("not json"))
+	require.Error(t, err)
+}
+
+// fakeNotifier is a Notifier whose Publish result is scripted per call, so publishBatch's retry
+// and marshal-error handling can be exercised without a real AWS client.
+type fakeNotifier struct {
+	mu      sync.Mutex
+	results []fakeNotifierResult
+	calls   int
+	closed  bool
+}
+
+type fakeNotifierResult struct {
+	failed map[int]error
+	err    error
+}
+
+func (f *fakeNotifier) Publish(ctx context.Context, batch []*events.S3Event) (map[int]error, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	result := f.results[f.calls]
+	f.calls++
+	return result.failed, result.err
+}
+
+func (f *fakeNotifier) Close() error {
+	f.closed = true
+	return nil
+}
+
+func testEvent(key string) *events.S3Event {
+	return &events.S3Event{Records: []events.S3EventRecord{{S3: events.S3Entity{
+		Bucket: events.S3Bucket{Name: "test-bucket"},
+		Object: events.S3Object{Key: key},
+	}}}}
+}
+
+func TestPublishBatchSuccess(t *testing.T) {
+	notifier := &fakeNotifier{results: []fakeNotifierResult{{failed: nil, err: nil}}}
+	errChan := make(chan error, 10)
+
+	err := publishBatch(context.Background(), notifier, []*events.S3Event{testEvent("a"), testEvent("b")}, errChan, metrics.New(nil))
+	require.NoError(t, err)
+	require.Equal(t, 1, notifier.calls)
+	close(errChan)
+	for e := range errChan {
+		t.Fatalf("unexpected error on errChan: %v", e)
+	}
+}
+
+func TestPublishBatchRetriesThenSucceeds(t *testing.T) {
+	notifier := &fakeNotifier{results: []fakeNotifierResult{
+		{failed: map[int]error{0: errors.New("throttled")}},
+		{failed: nil},
+	}}
+	errChan := make(chan error, 10)
+
+	err := publishBatch(context.Background(), notifier, []*events.S3Event{testEvent("a")}, errChan, metrics.New(nil))
+	require.NoError(t, err)
+	require.Equal(t, 2, notifier.calls)
+}
+
+func TestPublishBatchExhaustsRetries(t *testing.T) {
+	alwaysFails := fakeNotifierResult{failed: map[int]error{0: errors.New("rejected")}}
+	notifier := &fakeNotifier{results: []fakeNotifierResult{alwaysFails, alwaysFails, alwaysFails, alwaysFails}}
+	errChan := make(chan error, 10)
+
+	err := publishBatch(context.Background(), notifier, []*events.S3Event{testEvent("a")}, errChan, metrics.New(nil))
+	require.Error(t, err)
+	require.Equal(t, maxPublishRetries+1, notifier.calls)
+
+	close(errChan)
+	var errs []error
+	for e := range errChan {
+		errs = append(errs, e)
+	}
+	require.Len(t, errs, 1)
+}
+
+func TestPublishBatchMarshalErrorFailsFast(t *testing.T) {
+	notifier := &fakeNotifier{results: []fakeNotifierResult{
+		{failed: map[int]error{0: newMarshalError(errors.New("cannot marshal"))}},
+	}}
+	errChan := make(chan error, 10)
+
+	err := publishBatch(context.Background(), notifier, []*events.S3Event{testEvent("a")}, errChan, metrics.New(nil))
+	require.Error(t, err)
+	// A marshal error never gets retried: exactly one Publish call, not maxPublishRetries+1.
+	require.Equal(t, 1, notifier.calls)
+
+	close(errChan)
+	var errs []error
+	for e := range errChan {
+		errs = append(errs, e)
+	}
+	require.Len(t, errs, 1)
+}
+
+func TestPublishBatchAPIErrorFailsWholeBatch(t *testing.T) {
+	notifier := &fakeNotifier{results: []fakeNotifierResult{{err: errors.New("connection reset")}}}
+	errChan := make(chan error, 10)
+
+	err := publishBatch(context.Background(), notifier, []*events.S3Event{testEvent("a"), testEvent("b")}, errChan, metrics.New(nil))
+	require.Error(t, err)
+	require.Equal(t, 1, notifier.calls)
+
+	close(errChan)
+	var errs []error
+	for e := range errChan {
+		errs = append(errs, e)
+	}
+	require.Len(t, errs, 2)
+}
+
+// fakeS3Client is an s3iface.S3API backed by an in-memory list of keys, paginated testPageSize (2)
+// at a time regardless of the input's MaxKeys, so s3sns()'s listing/batching/publishing can be exercised end to end without AWS.
+type fakeS3Client struct {
+	s3iface.S3API
+	keys []string
+}
+
+func (f *fakeS3Client) ListObjectsV2Pages(input *s3.ListObjectsV2Input, fn func(*s3.ListObjectsV2Output, bool) bool) error {
+	const testPageSize = 2
+
+	start := 0
+	if input.StartAfter != nil {
+		for i, key := range f.keys {
+			if key == aws.StringValue(input.StartAfter) {
+				start = i + 1
+				break
+			}
+		}
+	}
+
+	for start < len(f.keys) {
+		end := start + testPageSize
+		if end > len(f.keys) {
+			end = len(f.keys)
+		}
+		page := &s3.ListObjectsV2Output{}
+		for _, key := range f.keys[start:end] {
+			key := key
+			page.Contents = append(page.Contents, &s3.Object{Key: &key, Size: aws.Int64(1)})
+		}
+		start = end
+		more := start < len(f.keys)
+		page.NextContinuationToken = aws.String("")
+		if !fn(page, more) {
+			return nil
+		}
+	}
+	return nil
+}
+
+func TestS3snsEndToEnd(t *testing.T) {
+	s3Client := &fakeS3Client{keys: []string{"a", "b", "c", "d", "e"}}
+	notifier := &fakeNotifier{results: []fakeNotifierResult{{}, {}, {}}}
+	var stats Stats
+
+	err := s3sns(s3Client, notifier, []string{"s3://test-bucket/prefix/"},
+		1, 10, time.Second, 0, time.Time{}, nil, nil, 0, metrics.New(nil), &stats)
+	require.NoError(t, err)
+	require.EqualValues(t, 5, stats.NumFiles)
+	require.EqualValues(t, 5, stats.NumBytes)
+}
+
+func TestS3NotifyClosesNotifier(t *testing.T) {
+	notifier := &fakeNotifier{results: []fakeNotifierResult{{}}}
+
+	var stats Stats
+	// An S3Path with date tokens but no From/To fails ExpandS3PathTemplate before the session is
+	// ever touched, so this exercises Close() without needing a real AWS client or network call.
+	cfg := Config{S3Path: "s3://bucket/{yyyy}/{mm}/{dd}/", Concurrency: 1}
+	err := S3Notify(nil, cfg, notifier, &stats)
+	require.Error(t, err)
+	require.True(t, notifier.closed)
+}
@@ -0,0 +1,217 @@
+package s3sns
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// dateTokenLayouts maps the CloudFormation-style date tokens an S3Path template may contain to
+// the Go reference-time layout used to render them for a given day.
+var dateTokenLayouts = map[string]string{
+	"{yyyy}": "2006",
+	"{mm}":   "01",
+	"{dd}":   "02",
+}
+
+// hasDateTokens reports whether template contains any token dateTokenLayouts understands.
+func hasDateTokens(template string) bool {
+	for token := range dateTokenLayouts {
+		if strings.Contains(template, token) {
+			return true
+		}
+	}
+	return false
+}
+
+// expandDateRange substitutes {yyyy}/{mm}/{dd} tokens in template once per day in [from, to]
+// (inclusive), de-duplicating adjacent days that render to the same string, e.g. a template with
+// only {yyyy}/{mm} changes once a month, not once a day.
+func expandDateRange(template string, from, to time.Time) ([]string, error) {
+	if from.IsZero() || to.IsZero() {
+		return nil, errors.Errorf("%q has date tokens but --from/--to were not set", template)
+	}
+	if to.Before(from) {
+		return nil, errors.Errorf("--to (%s) is before --from (%s)", to, from)
+	}
+
+	seen := make(map[string]bool)
+	var expanded []string
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		rendered := template
+		for token, layout := range dateTokenLayouts {
+			rendered = strings.ReplaceAll(rendered, token, day.Format(layout))
+		}
+		if !seen[rendered] {
+			seen[rendered] = true
+			expanded = append(expanded, rendered)
+		}
+	}
+	return expanded, nil
+}
+
+// expandBraces performs shell-style brace expansion on s: "{a,b,c}" expands to one copy of s per
+// comma-separated alternative, and "{01..12}" expands to one copy per number in the inclusive
+// range, zero-padded to match the wider bound. Expansion is recursive, so multiple brace groups
+// combine into the full cross product.
+func expandBraces(s string) ([]string, error) {
+	start := strings.IndexByte(s, '{')
+	if start == -1 {
+		return []string{s}, nil
+	}
+	end := matchingBrace(s, start)
+	if end == -1 {
+		return nil, errors.Errorf("unmatched '{' in %q", s)
+	}
+
+	prefix, group, suffix := s[:start], s[start+1:end], s[end+1:]
+	alternatives, err := braceAlternatives(group)
+	if err != nil {
+		return nil, errors.Wrapf(err, "bad brace expression %q", s[start:end+1])
+	}
+
+	var expanded []string
+	for _, alt := range alternatives {
+		rest, err := expandBraces(prefix + alt + suffix)
+		if err != nil {
+			return nil, err
+		}
+		expanded = append(expanded, rest...)
+	}
+	return expanded, nil
+}
+
+// matchingBrace returns the index of the '}' matching the '{' at s[open], or -1 if unmatched.
+func matchingBrace(s string, open int) int {
+	depth := 0
+	for i := open; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// braceAlternatives expands the inside of a single {...} group: "01..12" as a zero-padded
+// numeric range, otherwise "a,b,c" as a literal comma-separated list, split on commas at depth 0
+// so a nested group's own commas (e.g. the "{b,c}" in "{a,{b,c}}") aren't mistaken for separators
+// between this group's alternatives.
+func braceAlternatives(group string) ([]string, error) {
+	if lo, hi, width, ok := parseNumericRange(group); ok {
+		step := 1
+		if hi < lo {
+			step = -1
+		}
+		var alternatives []string
+		for n := lo; ; n += step {
+			alternatives = append(alternatives, fmt.Sprintf("%0*d", width, n))
+			if n == hi {
+				break
+			}
+		}
+		return alternatives, nil
+	}
+	return splitOutsideBraces(group), nil
+}
+
+// splitOutsideBraces splits s on commas that are not nested inside a {...} group.
+func splitOutsideBraces(s string) []string {
+	var alternatives []string
+	depth := 0
+	last := 0
+	for i, r := range s {
+		switch r {
+		case '{':
+			depth++
+		case '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				alternatives = append(alternatives, s[last:i])
+				last = i + 1
+			}
+		}
+	}
+	return append(alternatives, s[last:])
+}
+
+// parseNumericRange recognizes a "lo..hi" range like "01..12", returning the zero-padded width
+// of whichever bound is widest so "01..12" yields "01".."12", not "1".."12".
+func parseNumericRange(group string) (lo, hi, width int, ok bool) {
+	parts := strings.SplitN(group, "..", 2)
+	if len(parts) != 2 {
+		return 0, 0, 0, false
+	}
+	loStr, hiStr := parts[0], parts[1]
+	loN, err := strconv.Atoi(loStr)
+	if err != nil {
+		return 0, 0, 0, false
+	}
+	hiN, err := strconv.Atoi(hiStr)
+	if err != nil {
+		return 0, 0, 0, false
+	}
+	width = len(loStr)
+	if len(hiStr) > width {
+		width = len(hiStr)
+	}
+	return loN, hiN, width, true
+}
+
+// ExpandS3PathTemplate expands an S3Path template containing {yyyy}/{mm}/{dd} date tokens and/or
+// shell-style brace expressions ("{01..12}", "{a,b,c}") into the concrete, de-duplicated set of
+// s3:// paths it denotes. from/to bound date-token expansion; they're ignored if template has no
+// date tokens. A template with neither returns []string{template}.
+func ExpandS3PathTemplate(template string, from, to time.Time) ([]string, error) {
+	templates := []string{template}
+	if hasDateTokens(template) {
+		var err error
+		templates, err = expandDateRange(template, from, to)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	seen := make(map[string]bool)
+	var paths []string
+	for _, t := range templates {
+		expanded, err := expandBraces(t)
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range expanded {
+			if !seen[path] {
+				seen[path] = true
+				paths = append(paths, path)
+			}
+		}
+	}
+	return paths, nil
+}
@@ -0,0 +1,115 @@
+package s3sns
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandS3PathTemplateNoTokens(t *testing.T) {
+	paths, err := ExpandS3PathTemplate("s3://bucket/prefix/", time.Time{}, time.Time{})
+	require.NoError(t, err)
+	require.Equal(t, []string{"s3://bucket/prefix/"}, paths)
+}
+
+func TestExpandS3PathTemplateDateTokens(t *testing.T) {
+	from := time.Date(2024, 1, 30, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	paths, err := ExpandS3PathTemplate("s3://bucket/{yyyy}/{mm}/{dd}/", from, to)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"s3://bucket/2024/01/30/",
+		"s3://bucket/2024/01/31/",
+		"s3://bucket/2024/02/01/",
+	}, paths)
+}
+
+func TestExpandS3PathTemplateDateTokensRequireFromTo(t *testing.T) {
+	_, err := ExpandS3PathTemplate("s3://bucket/{yyyy}/{mm}/{dd}/", time.Time{}, time.Time{})
+	require.Error(t, err)
+
+	now := time.Now()
+	_, err = ExpandS3PathTemplate("s3://bucket/{yyyy}/{mm}/{dd}/", now, time.Time{})
+	require.Error(t, err)
+	_, err = ExpandS3PathTemplate("s3://bucket/{yyyy}/{mm}/{dd}/", time.Time{}, now)
+	require.Error(t, err)
+}
+
+func TestExpandS3PathTemplateToBeforeFrom(t *testing.T) {
+	from := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	_, err := ExpandS3PathTemplate("s3://bucket/{yyyy}/{mm}/{dd}/", from, to)
+	require.Error(t, err)
+}
+
+func TestExpandS3PathTemplateNumericRange(t *testing.T) {
+	paths, err := ExpandS3PathTemplate("s3://bucket/logs/{01..03}/", time.Time{}, time.Time{})
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"s3://bucket/logs/01/",
+		"s3://bucket/logs/02/",
+		"s3://bucket/logs/03/",
+	}, paths)
+}
+
+func TestExpandS3PathTemplateCommaList(t *testing.T) {
+	paths, err := ExpandS3PathTemplate("s3://bucket/{us-east-1,us-west-2}/logs/", time.Time{}, time.Time{})
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"s3://bucket/us-east-1/logs/",
+		"s3://bucket/us-west-2/logs/",
+	}, paths)
+}
+
+func TestExpandS3PathTemplateSiblingBraces(t *testing.T) {
+	paths, err := ExpandS3PathTemplate("s3://bucket/{01..02}/{a,b}/", time.Time{}, time.Time{})
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"s3://bucket/01/a/",
+		"s3://bucket/01/b/",
+		"s3://bucket/02/a/",
+		"s3://bucket/02/b/",
+	}, paths)
+}
+
+func TestExpandS3PathTemplateNestedBraces(t *testing.T) {
+	paths, err := ExpandS3PathTemplate("s3://bucket/{a,{b,c}}/", time.Time{}, time.Time{})
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"s3://bucket/a/",
+		"s3://bucket/b/",
+		"s3://bucket/c/",
+	}, paths)
+}
+
+func TestExpandS3PathTemplateUnmatchedBrace(t *testing.T) {
+	_, err := ExpandS3PathTemplate("s3://bucket/{a,b/", time.Time{}, time.Time{})
+	require.Error(t, err)
+}
+
+func TestExpandS3PathTemplateDeduplicates(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)
+	paths, err := ExpandS3PathTemplate("s3://bucket/{yyyy}/{mm}/", from, to)
+	require.NoError(t, err)
+	require.Equal(t, []string{"s3://bucket/2024/01/"}, paths)
+}
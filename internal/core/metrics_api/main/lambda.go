@@ -20,8 +20,10 @@ package main
 
 import (
 	"context"
+	"time"
 
 	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/prometheus/client_golang/prometheus"
 
 	"github.com/panther-labs/panther/api/lambda/metrics/models"
 	"github.com/panther-labs/panther/internal/core/metrics_api/api"
@@ -31,13 +33,36 @@ import (
 
 var router *genericapi.Router
 
+// registry holds this Lambda's own operational collectors, invocations and routeLatency. It is
+// private to this package: nothing else registers on it, and nothing in this binary serves it
+// for scraping or exports it, so the values are recorded but not exposed.
+var (
+	registry = prometheus.NewRegistry()
+
+	invocations = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "panther_metrics_api_invocations_total",
+		Help: "Number of metrics_api Lambda invocations.",
+	})
+	routeLatency = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "panther_metrics_api_route_latency_seconds",
+		Help:    "Latency of router.Handle for metrics_api requests.",
+		Buckets: prometheus.DefBuckets,
+	})
+)
+
 func init() {
 	router = genericapi.NewRouter("core", "metrics_api", nil, api.API{})
+	registry.MustRegister(invocations, routeLatency)
 }
 
 func lambdaHandler(ctx context.Context, request *models.LambdaInput) (interface{}, error) {
 	lambdalogger.ConfigureGlobal(ctx, nil)
-	return router.Handle(request)
+
+	invocations.Inc()
+	start := time.Now()
+	response, err := router.Handle(request)
+	routeLatency.Observe(time.Since(start).Seconds())
+	return response, err
 }
 
 func main() {